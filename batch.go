@@ -0,0 +1,104 @@
+package irc
+
+import (
+	"strings"
+	"time"
+)
+
+// Time returns the value of the IRCv3 "time" tag parsed as
+// RFC3339Nano, and whether the tag was present and valid.
+func (m *Message) Time() (time.Time, bool) {
+	v, ok := m.GetTag("time")
+	if !ok {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// MsgID returns the value of the IRCv3 "msgid" tag, and whether it
+// was present.
+func (m *Message) MsgID() (string, bool) {
+	return m.GetTag("msgid")
+}
+
+// Batch represents a completed IRCv3 batch, as started by a
+// "BATCH +<ref> <type> [params...]" line and closed by the matching
+// "BATCH -<ref>".
+type Batch struct {
+	// Type is the batch type, e.g. "chathistory" or "netjoin".
+	Type string
+
+	// Params are any parameters following Type on the BATCH start
+	// line.
+	Params []string
+
+	// Parent is the enclosing batch, if this batch was itself
+	// started inside another batch, or nil otherwise.
+	Parent *Batch
+
+	// Messages are the messages tagged with this batch's reference
+	// between its start and end lines.
+	Messages []*Message
+}
+
+// BatchTracker consumes a stream of messages and assembles completed
+// batches, including batches nested inside other batches.
+type BatchTracker struct {
+	open map[string]*Batch
+}
+
+// NewBatchTracker creates an empty BatchTracker.
+func NewBatchTracker() *BatchTracker {
+	return &BatchTracker{open: map[string]*Batch{}}
+}
+
+// Handle feeds m into the tracker. It returns the completed *Batch
+// when m is the BATCH line that closes one, and nil otherwise
+// (whether m started or was a member of a still-open batch, or isn't
+// part of any batch at all, in which case the caller should handle m
+// itself).
+func (t *BatchTracker) Handle(m *Message) *Batch {
+	if m.Command == "BATCH" && len(m.Params) >= 1 {
+		ref := m.Params[0]
+
+		switch {
+		case strings.HasPrefix(ref, "+") && len(m.Params) >= 2:
+			b := &Batch{
+				Type:   m.Params[1],
+				Params: append([]string{}, m.Params[2:]...),
+			}
+
+			if parentRef, ok := m.GetTag("batch"); ok {
+				b.Parent = t.open[parentRef]
+			}
+
+			t.open[ref[1:]] = b
+			return nil
+
+		case strings.HasPrefix(ref, "-"):
+			id := ref[1:]
+			b, ok := t.open[id]
+			if !ok {
+				return nil
+			}
+
+			delete(t.open, id)
+			return b
+		}
+	}
+
+	if ref, ok := m.GetTag("batch"); ok {
+		if b, ok := t.open[ref]; ok {
+			b.Messages = append(b.Messages, m)
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package irc
+
+import "testing"
+
+func mustParse(t *testing.T, line string) *Message {
+	t.Helper()
+
+	m := ParseMessage(line)
+	if m == nil {
+		t.Fatalf("ParseMessage(%q) = nil", line)
+	}
+
+	return m
+}
+
+func TestBatchTrackerSimple(t *testing.T) {
+	tr := NewBatchTracker()
+
+	start := mustParse(t, "BATCH +abc chathistory #chan")
+	if b := tr.Handle(start); b != nil {
+		t.Fatalf("Handle(start) = %+v, want nil", b)
+	}
+
+	msg := mustParse(t, "@batch=abc :nick!user@host PRIVMSG #chan :hello")
+	if b := tr.Handle(msg); b != nil {
+		t.Fatalf("Handle(msg) = %+v, want nil", b)
+	}
+
+	end := mustParse(t, "BATCH -abc")
+	b := tr.Handle(end)
+	if b == nil {
+		t.Fatal("Handle(end) = nil, want a completed Batch")
+	}
+
+	if b.Type != "chathistory" {
+		t.Errorf("Batch.Type = %q, want chathistory", b.Type)
+	}
+	if len(b.Params) != 1 || b.Params[0] != "#chan" {
+		t.Errorf("Batch.Params = %v, want [#chan]", b.Params)
+	}
+	if len(b.Messages) != 1 || b.Messages[0].Command != "PRIVMSG" {
+		t.Errorf("Batch.Messages = %+v, want one PRIVMSG", b.Messages)
+	}
+	if b.Parent != nil {
+		t.Errorf("Batch.Parent = %+v, want nil", b.Parent)
+	}
+}
+
+func TestBatchTrackerNested(t *testing.T) {
+	tr := NewBatchTracker()
+
+	tr.Handle(mustParse(t, "BATCH +outer netjoin"))
+
+	inner := mustParse(t, "@batch=outer BATCH +inner chathistory #chan")
+	if b := tr.Handle(inner); b != nil {
+		t.Fatalf("Handle(inner start) = %+v, want nil", b)
+	}
+
+	tr.Handle(mustParse(t, "@batch=inner :nick!user@host PRIVMSG #chan :hi"))
+
+	innerBatch := tr.Handle(mustParse(t, "BATCH -inner"))
+	if innerBatch == nil {
+		t.Fatal("Handle(inner end) = nil, want a completed Batch")
+	}
+	if innerBatch.Type != "chathistory" {
+		t.Errorf("innerBatch.Type = %q, want chathistory", innerBatch.Type)
+	}
+	if innerBatch.Parent == nil || innerBatch.Parent.Type != "netjoin" {
+		t.Fatalf("innerBatch.Parent = %+v, want a netjoin batch", innerBatch.Parent)
+	}
+	if len(innerBatch.Messages) != 1 {
+		t.Errorf("innerBatch.Messages = %+v, want one message", innerBatch.Messages)
+	}
+
+	outerBatch := tr.Handle(mustParse(t, "BATCH -outer"))
+	if outerBatch == nil || outerBatch.Type != "netjoin" {
+		t.Fatalf("Handle(outer end) = %+v, want a netjoin batch", outerBatch)
+	}
+}
+
+func TestBatchTrackerUnrelatedMessagePassesThrough(t *testing.T) {
+	tr := NewBatchTracker()
+
+	if b := tr.Handle(mustParse(t, "PING :irc.example.com")); b != nil {
+		t.Errorf("Handle(unrelated) = %+v, want nil", b)
+	}
+}
+
+func TestBatchTrackerUnknownEndIgnored(t *testing.T) {
+	tr := NewBatchTracker()
+
+	if b := tr.Handle(mustParse(t, "BATCH -never-opened")); b != nil {
+		t.Errorf("Handle(unknown end) = %+v, want nil", b)
+	}
+}
+
+func TestMessageTimeAndMsgID(t *testing.T) {
+	m := mustParse(t, "@time=2019-02-26T21:20:05.123Z;msgid=abc123 :nick!user@host PRIVMSG #chan :hi")
+
+	ts, ok := m.Time()
+	if !ok {
+		t.Fatal("Time() ok = false, want true")
+	}
+	if ts.Year() != 2019 || ts.Month() != 2 || ts.Day() != 26 {
+		t.Errorf("Time() = %v, want 2019-02-26", ts)
+	}
+
+	if id, ok := m.MsgID(); !ok || id != "abc123" {
+		t.Errorf("MsgID() = %q, %v, want %q, true", id, ok, "abc123")
+	}
+}
+
+func TestMessageTimeMissing(t *testing.T) {
+	m := mustParse(t, ":nick!user@host PRIVMSG #chan :hi")
+
+	if _, ok := m.Time(); ok {
+		t.Error("Time() ok = true for a message without a time tag")
+	}
+	if _, ok := m.MsgID(); ok {
+		t.Error("MsgID() ok = true for a message without a msgid tag")
+	}
+}
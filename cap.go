@@ -0,0 +1,215 @@
+package irc
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// saslChunkSize is the maximum size, in bytes, of a base64-encoded
+// AUTHENTICATE line, per the IRCv3 SASL specification.
+const saslChunkSize = 400
+
+// Caps returns the capabilities the server acknowledged during
+// negotiation, keyed by name, with any "key=value" values from CAP
+// LS included.
+func (c *Client) Caps() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ret := make(map[string]string, len(c.caps))
+	for k, v := range c.caps {
+		ret[k] = v
+	}
+
+	return ret
+}
+
+// negotiate runs IRCv3 capability negotiation and, if configured,
+// SASL authentication, before registration. It must be called before
+// NICK/USER are sent.
+func (c *Client) negotiate() error {
+	if err := c.Writef("CAP", "LS", "302"); err != nil {
+		return err
+	}
+
+	available, err := c.readCapLS()
+	if err != nil {
+		return err
+	}
+
+	wanted := map[string]bool{}
+	var want []string
+	addWant := func(name string) {
+		if !wanted[name] {
+			wanted[name] = true
+			want = append(want, name)
+		}
+	}
+
+	for _, name := range c.config.Caps {
+		if _, ok := available[name]; ok {
+			addWant(name)
+		}
+	}
+
+	if c.config.SASL != nil {
+		if _, ok := available["sasl"]; !ok {
+			return fmt.Errorf("irc: SASL is configured but the server does not advertise the sasl capability")
+		}
+
+		addWant("sasl")
+	}
+
+	if len(want) > 0 {
+		if err := c.Writef("CAP", "REQ", strings.Join(want, " ")); err != nil {
+			return err
+		}
+
+		if err := c.readCapACK(available); err != nil {
+			return err
+		}
+	}
+
+	if c.config.SASL != nil {
+		if _, ok := c.Caps()["sasl"]; !ok {
+			return fmt.Errorf("irc: server rejected the sasl capability request")
+		}
+
+		if err := c.authenticate(); err != nil {
+			return err
+		}
+	}
+
+	return c.Writef("CAP", "END")
+}
+
+// readCapLS reads CAP LS replies, handling multi-line continuations,
+// and returns the advertised capabilities and their values.
+func (c *Client) readCapLS() (map[string]string, error) {
+	available := map[string]string{}
+
+	for {
+		m, err := c.conn.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+
+		if m.Command != "CAP" || len(m.Params) < 3 || m.Params[1] != "LS" {
+			c.handle(m)
+			continue
+		}
+
+		more := m.Params[2] == "*"
+		list := m.Params[len(m.Params)-1]
+
+		for _, entry := range strings.Fields(list) {
+			name, value, _ := strings.Cut(entry, "=")
+			available[name] = value
+		}
+
+		if !more {
+			return available, nil
+		}
+	}
+}
+
+// readCapACK waits for the server's CAP ACK/NAK reply to our CAP
+// REQ, recording any acknowledged capabilities.
+func (c *Client) readCapACK(available map[string]string) error {
+	for {
+		m, err := c.conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		if m.Command != "CAP" || len(m.Params) < 3 {
+			c.handle(m)
+			continue
+		}
+
+		switch m.Params[1] {
+		case "ACK":
+			c.mu.Lock()
+			for _, name := range strings.Fields(m.Params[len(m.Params)-1]) {
+				name = strings.TrimPrefix(name, "-")
+				c.caps[name] = available[name]
+			}
+			c.mu.Unlock()
+			return nil
+
+		case "NAK":
+			return nil
+
+		default:
+			c.handle(m)
+		}
+	}
+}
+
+// authenticate drives the AUTHENTICATE exchange for c.config.SASL.
+func (c *Client) authenticate() error {
+	mech := c.config.SASL
+
+	if err := c.Writef("AUTHENTICATE", mech.Name()); err != nil {
+		return err
+	}
+
+	for {
+		m, err := c.conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		switch m.Command {
+		case "AUTHENTICATE":
+			if len(m.Params) < 1 || m.Params[0] != "+" {
+				continue
+			}
+
+			if err := c.sendSASLResponse(mech.Response()); err != nil {
+				return err
+			}
+
+		case "900", "903":
+			return nil
+
+		case "904", "905":
+			return fmt.Errorf("irc: SASL authentication failed (%s)", m.Command)
+
+		default:
+			c.handle(m)
+		}
+	}
+}
+
+// sendSASLResponse base64-encodes response and sends it as one or
+// more AUTHENTICATE lines, chunked to saslChunkSize bytes, with a
+// trailing "AUTHENTICATE +" when the encoded response is empty or a
+// multiple of saslChunkSize.
+func (c *Client) sendSASLResponse(response string) error {
+	encoded := base64.StdEncoding.EncodeToString([]byte(response))
+
+	if encoded == "" {
+		return c.Writef("AUTHENTICATE", "+")
+	}
+
+	for len(encoded) > 0 {
+		chunk := encoded
+		if len(chunk) > saslChunkSize {
+			chunk = chunk[:saslChunkSize]
+		}
+
+		if err := c.Writef("AUTHENTICATE", chunk); err != nil {
+			return err
+		}
+
+		encoded = encoded[len(chunk):]
+
+		if len(chunk) == saslChunkSize && len(encoded) == 0 {
+			return c.Writef("AUTHENTICATE", "+")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package irc
+
+import (
+	"net"
+	"testing"
+)
+
+// newNegotiateTestClient returns a Client wired up to one end of an
+// in-memory pipe, ready to have negotiate driven against the other
+// end (returned as a *Conn for convenience).
+func newNegotiateTestClient(t *testing.T, config ClientConfig) (*Client, *Conn) {
+	t.Helper()
+
+	clientSide, serverSide := net.Pipe()
+	t.Cleanup(func() {
+		clientSide.Close()
+		serverSide.Close()
+	})
+
+	c := &Client{
+		config: config,
+		conn:   NewConn(clientSide),
+		caps:   map[string]string{},
+	}
+
+	return c, NewConn(serverSide)
+}
+
+func TestNegotiateRequestsAndAcksAdvertisedCaps(t *testing.T) {
+	c, server := newNegotiateTestClient(t, ClientConfig{
+		Caps: []string{"server-time", "message-tags", "not-advertised"},
+	})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.negotiate() }()
+
+	ls, err := server.ReadMessage()
+	if err != nil || ls.Command != "CAP" || ls.Params[0] != "LS" {
+		t.Fatalf("expected CAP LS, got %+v, err %v", ls, err)
+	}
+
+	if err := server.Writef("CAP", "*", "LS", "server-time message-tags"); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := server.ReadMessage()
+	if err != nil || req.Command != "CAP" || req.Params[0] != "REQ" {
+		t.Fatalf("expected CAP REQ, got %+v, err %v", req, err)
+	}
+
+	if got, want := req.Trailing(), "server-time message-tags"; got != want {
+		t.Errorf("CAP REQ list = %q, want %q (not-advertised must be excluded)", got, want)
+	}
+
+	if err := server.Writef("CAP", "*", "ACK", req.Trailing()); err != nil {
+		t.Fatal(err)
+	}
+
+	end, err := server.ReadMessage()
+	if err != nil || end.Command != "CAP" || end.Params[0] != "END" {
+		t.Fatalf("expected CAP END, got %+v, err %v", end, err)
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("negotiate() error = %v", err)
+	}
+
+	caps := c.Caps()
+	if _, ok := caps["server-time"]; !ok {
+		t.Error("expected server-time to be recorded as acknowledged")
+	}
+	if _, ok := caps["message-tags"]; !ok {
+		t.Error("expected message-tags to be recorded as acknowledged")
+	}
+}
+
+func TestNegotiateHandlesNAK(t *testing.T) {
+	c, server := newNegotiateTestClient(t, ClientConfig{Caps: []string{"server-time"}})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.negotiate() }()
+
+	if _, err := server.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Writef("CAP", "*", "LS", "server-time"); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := server.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Writef("CAP", "*", "NAK", req.Trailing()); err != nil {
+		t.Fatal(err)
+	}
+
+	end, err := server.ReadMessage()
+	if err != nil || end.Command != "CAP" || end.Params[0] != "END" {
+		t.Fatalf("expected CAP END after a NAK, got %+v, err %v", end, err)
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("negotiate() error = %v", err)
+	}
+
+	if caps := c.Caps(); len(caps) != 0 {
+		t.Errorf("Caps() = %v, want empty after a NAK", caps)
+	}
+}
+
+func TestNegotiateMultilineCapLS(t *testing.T) {
+	c, server := newNegotiateTestClient(t, ClientConfig{Caps: []string{"sasl"}})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.negotiate() }()
+
+	if _, err := server.ReadMessage(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := server.Writef("CAP", "*", "LS", "*", "server-time"); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Writef("CAP", "*", "LS", "sasl=PLAIN,EXTERNAL"); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := server.ReadMessage()
+	if err != nil || req.Trailing() != "sasl" {
+		t.Fatalf("expected CAP REQ for sasl after the continuation, got %+v, err %v", req, err)
+	}
+
+	if err := server.Writef("CAP", "*", "ACK", "sasl"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := server.ReadMessage(); err != nil { // CAP END
+		t.Fatal(err)
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("negotiate() error = %v", err)
+	}
+}
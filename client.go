@@ -0,0 +1,265 @@
+package irc
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Handler handles a single Message received by a Client.
+type Handler interface {
+	Handle(c *Client, m *Message)
+}
+
+// HandlerFunc adapts a plain function to the Handler interface.
+type HandlerFunc func(c *Client, m *Message)
+
+// Handle calls f(c, m).
+func (f HandlerFunc) Handle(c *Client, m *Message) {
+	f(c, m)
+}
+
+// Mux routes incoming messages to handlers registered against a
+// Message's Command (including numeric replies such as "001" or
+// "433"). Handlers registered for the same command are called in
+// registration order. If no handler is registered for a command, it
+// is passed to Default, if set.
+type Mux struct {
+	// Default is called for any message whose Command has no
+	// registered handler.
+	Default Handler
+
+	handlers map[string][]Handler
+}
+
+// NewMux creates an empty Mux.
+func NewMux() *Mux {
+	return &Mux{handlers: map[string][]Handler{}}
+}
+
+// AddHandler registers handler to be called for every message with
+// the given command.
+func (mx *Mux) AddHandler(command string, handler Handler) {
+	mx.handlers[command] = append(mx.handlers[command], handler)
+}
+
+// AddHandlerFunc is a convenience wrapper around AddHandler for plain
+// functions.
+func (mx *Mux) AddHandlerFunc(command string, f func(c *Client, m *Message)) {
+	mx.AddHandler(command, HandlerFunc(f))
+}
+
+// Handle implements Handler by dispatching m to the handlers
+// registered for m.Command, falling through to Default if none are
+// registered.
+func (mx *Mux) Handle(c *Client, m *Message) {
+	handlers, ok := mx.handlers[m.Command]
+	if !ok {
+		if mx.Default != nil {
+			mx.Default.Handle(c, m)
+		}
+		return
+	}
+
+	for _, handler := range handlers {
+		handler.Handle(c, m)
+	}
+}
+
+// Dialer is called to establish the connection a Client reads and
+// writes over. It is called once to connect, and again for every
+// reconnect attempt when ClientConfig.AutoReconnect is set.
+type Dialer func() (io.ReadWriter, error)
+
+// ClientConfig configures a Client.
+type ClientConfig struct {
+	// Nick is the initial nickname to register with.
+	Nick string
+
+	// User is the username sent in the USER command.
+	User string
+
+	// Name is the "real name" sent in the USER command.
+	Name string
+
+	// Pass, if set, is sent as a PASS command before registration.
+	Pass string
+
+	// Handler receives every message read from the connection,
+	// after the Client's own bookkeeping (PING/PONG, nick
+	// collisions, and so on) has run. The CAP LS/ACK/NAK replies,
+	// AUTHENTICATE lines and SASL result numerics consumed during
+	// capability negotiation are not passed to it.
+	Handler Handler
+
+	// AutoReconnect causes Run to reconnect with exponential backoff
+	// instead of returning when the connection is lost.
+	AutoReconnect bool
+
+	// Caps lists the IRCv3 capabilities to request during
+	// negotiation. Only those the server also advertises are
+	// requested.
+	Caps []string
+
+	// SASL, if set, is used to authenticate during capability
+	// negotiation.
+	SASL SASLMechanism
+}
+
+const (
+	initialReconnectBackoff = 1 * time.Second
+	maxReconnectBackoff     = 2 * time.Minute
+)
+
+// Client drives a registered IRC session: it connects, registers,
+// answers PING transparently, and dispatches every other message to
+// ClientConfig.Handler.
+type Client struct {
+	dial   Dialer
+	config ClientConfig
+
+	mu          sync.Mutex
+	conn        *Conn
+	currentNick string
+	caps        map[string]string
+}
+
+// NewClient creates a Client which dials connections with dial and
+// configures itself from config.
+func NewClient(dial Dialer, config ClientConfig) *Client {
+	return &Client{
+		dial:        dial,
+		config:      config,
+		currentNick: config.Nick,
+		caps:        map[string]string{},
+	}
+}
+
+// CurrentNick returns the nickname the Client is currently using,
+// which may differ from ClientConfig.Nick if the server rejected it.
+func (c *Client) CurrentNick() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.currentNick
+}
+
+// Write writes a raw, pre-formatted line to the server. It is safe
+// to call concurrently with Run.
+func (c *Client) Write(line string) error {
+	return c.currentConn().Write(line)
+}
+
+// WriteMessage writes m to the server. It is safe to call
+// concurrently with Run.
+func (c *Client) WriteMessage(m *Message) error {
+	return c.currentConn().WriteMessage(m)
+}
+
+// Writef builds a Message from command and params and writes it to
+// the server. It is safe to call concurrently with Run.
+func (c *Client) Writef(command string, params ...string) error {
+	return c.currentConn().Writef(command, params...)
+}
+
+// currentConn returns the Conn for the connection currently in use,
+// guarding against the reconnect in runOnce replacing c.conn
+// concurrently.
+func (c *Client) currentConn() *Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn
+}
+
+// Run connects and registers the Client, then blocks reading and
+// dispatching messages until the connection closes. If
+// ClientConfig.AutoReconnect is set, Run reconnects with exponential
+// backoff instead of returning on a lost connection; otherwise it
+// returns the error that ended the connection.
+func (c *Client) Run() error {
+	backoff := initialReconnectBackoff
+
+	for {
+		err := c.runOnce()
+
+		if !c.config.AutoReconnect {
+			return err
+		}
+
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+}
+
+func (c *Client) runOnce() error {
+	rw, err := c.dial()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.conn = NewConn(rw)
+	c.currentNick = c.config.Nick
+	c.caps = map[string]string{}
+	c.mu.Unlock()
+
+	if err := c.negotiate(); err != nil {
+		return err
+	}
+
+	if err := c.register(); err != nil {
+		return err
+	}
+
+	for {
+		m, err := c.conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		c.handle(m)
+	}
+}
+
+func (c *Client) register() error {
+	if c.config.Pass != "" {
+		if err := c.Writef("PASS", c.config.Pass); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Writef("NICK", c.CurrentNick()); err != nil {
+		return err
+	}
+
+	return c.Writef("USER", c.config.User, "0", "*", c.config.Name)
+}
+
+func (c *Client) handle(m *Message) {
+	switch m.Command {
+	case "PING":
+		c.Writef("PONG", m.Params...)
+
+	case "001":
+		if len(m.Params) > 0 {
+			c.mu.Lock()
+			c.currentNick = m.Params[0]
+			c.mu.Unlock()
+		}
+
+	case "433", "432":
+		c.mu.Lock()
+		c.currentNick += "_"
+		nick := c.currentNick
+		c.mu.Unlock()
+
+		c.Writef("NICK", nick)
+	}
+
+	if c.config.Handler != nil {
+		c.config.Handler.Handle(c, m)
+	}
+}
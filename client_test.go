@@ -0,0 +1,156 @@
+package irc
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMuxDispatchesInRegistrationOrder(t *testing.T) {
+	mx := NewMux()
+
+	var order []int
+	mx.AddHandlerFunc("PRIVMSG", func(c *Client, m *Message) { order = append(order, 1) })
+	mx.AddHandlerFunc("PRIVMSG", func(c *Client, m *Message) { order = append(order, 2) })
+
+	mx.Handle(nil, &Message{Command: "PRIVMSG"})
+
+	if want := []int{1, 2}; len(order) != len(want) || order[0] != want[0] || order[1] != want[1] {
+		t.Errorf("dispatch order = %v, want %v", order, want)
+	}
+}
+
+func TestMuxFallsThroughToDefault(t *testing.T) {
+	mx := NewMux()
+
+	var gotDefault, gotJoin bool
+	mx.Default = HandlerFunc(func(c *Client, m *Message) { gotDefault = true })
+	mx.AddHandlerFunc("JOIN", func(c *Client, m *Message) { gotJoin = true })
+
+	mx.Handle(nil, &Message{Command: "PRIVMSG"})
+	if !gotDefault {
+		t.Error("expected Default to be called for a command with no registered handler")
+	}
+
+	mx.Handle(nil, &Message{Command: "JOIN"})
+	if !gotJoin {
+		t.Error("expected the JOIN handler to be called")
+	}
+}
+
+// newHandleTestClient returns a Client wired up to one end of an
+// in-memory pipe, with currentNick set to nick, ready to have handle
+// driven directly (bypassing negotiate/register).
+func newHandleTestClient(t *testing.T, nick string) (*Client, *Conn) {
+	t.Helper()
+
+	clientSide, serverSide := net.Pipe()
+	t.Cleanup(func() {
+		clientSide.Close()
+		serverSide.Close()
+	})
+
+	c := &Client{
+		conn:        NewConn(clientSide),
+		currentNick: nick,
+		caps:        map[string]string{},
+	}
+
+	return c, NewConn(serverSide)
+}
+
+func TestClientHandlePingRepliesWithPong(t *testing.T) {
+	c, server := newHandleTestClient(t, "bot")
+
+	go c.handle(&Message{Command: "PING", Params: []string{"irc.example.com"}})
+
+	pong, err := server.ReadMessage()
+	if err != nil || pong.Command != "PONG" || pong.Trailing() != "irc.example.com" {
+		t.Fatalf("expected PONG irc.example.com, got %+v, err %v", pong, err)
+	}
+}
+
+func TestClientHandle001SyncsNick(t *testing.T) {
+	c, _ := newHandleTestClient(t, "bot")
+
+	c.handle(&Message{Command: "001", Params: []string{"bot_actual", "Welcome"}})
+
+	if got := c.CurrentNick(); got != "bot_actual" {
+		t.Errorf("CurrentNick() = %q, want %q", got, "bot_actual")
+	}
+}
+
+func TestClientHandleNickCollisionAppendsUnderscore(t *testing.T) {
+	for _, numeric := range []string{"433", "432"} {
+		c, server := newHandleTestClient(t, "bot")
+
+		go c.handle(&Message{Command: numeric, Params: []string{"*", "bot", "Nickname is already in use"}})
+
+		nick, err := server.ReadMessage()
+		if err != nil || nick.Command != "NICK" || nick.Params[0] != "bot_" {
+			t.Fatalf("%s: expected NICK bot_, got %+v, err %v", numeric, nick, err)
+		}
+		if got := c.CurrentNick(); got != "bot_" {
+			t.Errorf("%s: CurrentNick() = %q, want %q", numeric, got, "bot_")
+		}
+	}
+}
+
+func TestClientHandleDispatchesToConfiguredHandler(t *testing.T) {
+	var got *Message
+	c, _ := newHandleTestClient(t, "bot")
+	c.config.Handler = HandlerFunc(func(c *Client, m *Message) { got = m })
+
+	msg := &Message{Command: "PRIVMSG", Params: []string{"#chan", "hi"}}
+	c.handle(msg)
+
+	if got != msg {
+		t.Error("expected the configured Handler to receive the message")
+	}
+}
+
+func TestRunReturnsImmediatelyWithoutAutoReconnect(t *testing.T) {
+	dialErr := errors.New("dial failed")
+
+	c := NewClient(func() (io.ReadWriter, error) { return nil, dialErr }, ClientConfig{})
+
+	if err := c.Run(); err != dialErr {
+		t.Errorf("Run() error = %v, want %v", err, dialErr)
+	}
+}
+
+func TestRunRetriesWithBackoffWhenAutoReconnect(t *testing.T) {
+	var attempts int32
+
+	dial := func() (io.ReadWriter, error) {
+		atomic.AddInt32(&attempts, 1)
+
+		clientSide, serverSide := net.Pipe()
+		serverSide.Close() // every attempt fails immediately on its first write (CAP LS)
+
+		return clientSide, nil
+	}
+
+	c := NewClient(dial, ClientConfig{AutoReconnect: true})
+	go c.Run()
+
+	// initialReconnectBackoff is 1s, so a second dial attempt before
+	// that has elapsed would mean Run isn't backing off between
+	// retries.
+	time.Sleep(500 * time.Millisecond)
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("attempts after 500ms = %d, want 1 (Run should still be backing off)", got)
+	}
+
+	deadline := time.After(3 * time.Second)
+	for atomic.LoadInt32(&attempts) < 2 {
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for Run to retry after the first failed connection")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package irc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxLineLength is the maximum size, in bytes, of a line as defined
+// by the IRC protocol (512 bytes, including the trailing CR-LF).
+const MaxLineLength = 512
+
+// MaxTagLength is the additional budget, in bytes, that IRCv3
+// message-tags are allowed to add on top of MaxLineLength.
+const MaxTagLength = 8191
+
+// Conn wraps an io.ReadWriter (typically a net.Conn or tls.Conn) and
+// reads and writes whole IRC messages over it. It does not interpret
+// messages in any way; see Client for that.
+type Conn struct {
+	rw io.ReadWriter
+	r  *bufio.Reader
+
+	// MaxWriteLength is the maximum length, in bytes, of a line this
+	// Conn will write, including the trailing "\r\n" — the same
+	// units as the MaxLineLength constant, so setting this to
+	// MaxLineLength enforces the plain 512-byte protocol limit. It
+	// defaults to MaxLineLength plus MaxTagLength, to accommodate
+	// IRCv3 message-tags.
+	MaxWriteLength int
+}
+
+// NewConn creates a Conn which reads and writes messages over rw.
+func NewConn(rw io.ReadWriter) *Conn {
+	return &Conn{
+		rw:             rw,
+		r:              bufio.NewReader(rw),
+		MaxWriteLength: MaxLineLength + MaxTagLength,
+	}
+}
+
+// ReadMessage reads a single line from the connection, up to and
+// including the terminating "\r\n", and parses it into a Message. It
+// returns the underlying error (io.EOF included) when the connection
+// is closed.
+func (c *Conn) ReadMessage() (*Message, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+
+	m := ParseMessage(line)
+	if m == nil {
+		return nil, fmt.Errorf("irc: invalid message: %q", line)
+	}
+
+	return m, nil
+}
+
+// Write writes a raw, pre-formatted line to the connection,
+// appending the "\r\n" terminator. Most callers should prefer
+// WriteMessage or Writef.
+func (c *Conn) Write(line string) error {
+	if len(line)+2 > c.MaxWriteLength {
+		return fmt.Errorf("irc: message too long: %d > %d bytes", len(line)+2, c.MaxWriteLength)
+	}
+
+	_, err := io.WriteString(c.rw, line+"\r\n")
+	return err
+}
+
+// WriteMessage encodes m and writes it to the connection.
+func (c *Conn) WriteMessage(m *Message) error {
+	return c.Write(m.String())
+}
+
+// Writef builds a Message from command and params and writes it to
+// the connection in one call.
+func (c *Conn) Writef(command string, params ...string) error {
+	return c.WriteMessage(&Message{
+		Command: command,
+		Params:  params,
+	})
+}
@@ -0,0 +1,97 @@
+package irc
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestConnWriteMessageReadMessage(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	clientConn := NewConn(client)
+	serverConn := NewConn(server)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- clientConn.WriteMessage(&Message{
+			Command: "PRIVMSG",
+			Params:  []string{"#chan", "hello there"},
+		})
+	}()
+
+	m, err := serverConn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+
+	if m.Command != "PRIVMSG" || m.Trailing() != "hello there" {
+		t.Errorf("ReadMessage() = %+v, want PRIVMSG with trailing %q", m, "hello there")
+	}
+}
+
+func TestConnWritef(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	clientConn := NewConn(client)
+	serverConn := NewConn(server)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- clientConn.Writef("NICK", "bot") }()
+
+	m, err := serverConn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("Writef() error = %v", err)
+	}
+
+	if m.Command != "NICK" || len(m.Params) != 1 || m.Params[0] != "bot" {
+		t.Errorf("ReadMessage() = %+v, want NICK bot", m)
+	}
+}
+
+func TestConnWriteEnforcesMaxWriteLength(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	conn := NewConn(client)
+	conn.MaxWriteLength = 10
+
+	if err := conn.Write(strings.Repeat("a", 20)); err == nil {
+		t.Fatal("Write() error = nil, want an error for a line over MaxWriteLength")
+	}
+}
+
+func TestConnReadMessageInvalidLine(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	serverConn := NewConn(server)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := client.Write([]byte("\r\n"))
+		errCh <- err
+	}()
+
+	if _, err := serverConn.ReadMessage(); err == nil {
+		t.Fatal("ReadMessage() error = nil, want an error for an empty line")
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("raw write error = %v", err)
+	}
+}
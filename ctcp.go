@@ -0,0 +1,104 @@
+package irc
+
+import "strings"
+
+// ctcpDelim is the byte CTCP uses to delimit the extended-data
+// portion of a PRIVMSG/NOTICE trailing parameter.
+const ctcpDelim = '\x01'
+
+// ctcpQuoteMap maps bytes that can't appear literally inside a CTCP
+// message to their M-QUOTE escape character.
+var ctcpQuoteMap = map[rune]rune{
+	'\x00': '0',
+	'\n':   'n',
+	'\r':   'r',
+	'\x10': '\x10',
+}
+
+var ctcpUnquoteMap = map[rune]rune{
+	'0':    '\x00',
+	'n':    '\n',
+	'r':    '\r',
+	'\x10': '\x10',
+}
+
+// ctcpQuote escapes bytes that are unsafe for the CTCP low-level
+// quoting (M-QUOTE) layer, using \x10 as the escape character.
+func ctcpQuote(s string) string {
+	buf := &strings.Builder{}
+
+	for _, c := range s {
+		if r, ok := ctcpQuoteMap[c]; ok {
+			buf.WriteByte('\x10')
+			buf.WriteRune(r)
+		} else {
+			buf.WriteRune(c)
+		}
+	}
+
+	return buf.String()
+}
+
+// ctcpUnquote reverses ctcpQuote.
+func ctcpUnquote(s string) string {
+	buf := &strings.Builder{}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\x10' && i+1 < len(runes) {
+			i++
+			if r, ok := ctcpUnquoteMap[runes[i]]; ok {
+				buf.WriteRune(r)
+			} else {
+				buf.WriteRune(runes[i])
+			}
+			continue
+		}
+
+		buf.WriteRune(runes[i])
+	}
+
+	return buf.String()
+}
+
+// CTCP recognizes a PRIVMSG or NOTICE whose trailing parameter is a
+// CTCP message (wrapped in \x01...\x01) and splits it into its
+// command and params. ok is false if m is not a CTCP message.
+func (m *Message) CTCP() (command, params string, ok bool) {
+	if m.Command != "PRIVMSG" && m.Command != "NOTICE" {
+		return "", "", false
+	}
+
+	trailing := m.Trailing()
+	if len(trailing) < 2 || trailing[0] != ctcpDelim || trailing[len(trailing)-1] != ctcpDelim {
+		return "", "", false
+	}
+
+	body := ctcpUnquote(trailing[1 : len(trailing)-1])
+
+	command, params, _ = strings.Cut(body, " ")
+	return strings.ToUpper(command), params, true
+}
+
+// NewCTCP builds a PRIVMSG to target containing a CTCP query.
+func NewCTCP(target, command, params string) *Message {
+	return newCTCPMessage("PRIVMSG", target, command, params)
+}
+
+// NewCTCPReply builds a NOTICE to target containing a CTCP reply, as
+// is conventional when responding to a CTCP query.
+func NewCTCPReply(target, command, params string) *Message {
+	return newCTCPMessage("NOTICE", target, command, params)
+}
+
+func newCTCPMessage(verb, target, command, params string) *Message {
+	body := command
+	if params != "" {
+		body += " " + params
+	}
+
+	return &Message{
+		Command: verb,
+		Params:  []string{target, string(ctcpDelim) + ctcpQuote(body) + string(ctcpDelim)},
+	}
+}
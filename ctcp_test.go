@@ -0,0 +1,79 @@
+package irc
+
+import "testing"
+
+func TestCTCPRoundTrip(t *testing.T) {
+	m := NewCTCP("#chan", "ACTION", "waves")
+
+	if m.Command != "PRIVMSG" {
+		t.Fatalf("NewCTCP() Command = %q, want PRIVMSG", m.Command)
+	}
+
+	command, params, ok := m.CTCP()
+	if !ok {
+		t.Fatal("CTCP() ok = false, want true")
+	}
+	if command != "ACTION" || params != "waves" {
+		t.Errorf("CTCP() = %q, %q, want %q, %q", command, params, "ACTION", "waves")
+	}
+}
+
+func TestNewCTCPReply(t *testing.T) {
+	m := NewCTCPReply("nick", "VERSION", "irc-bot 1.0")
+
+	if m.Command != "NOTICE" {
+		t.Fatalf("NewCTCPReply() Command = %q, want NOTICE", m.Command)
+	}
+
+	command, params, ok := m.CTCP()
+	if !ok || command != "VERSION" || params != "irc-bot 1.0" {
+		t.Errorf("CTCP() = %q, %q, %v, want %q, %q, true", command, params, ok, "VERSION", "irc-bot 1.0")
+	}
+}
+
+func TestCTCPNoParams(t *testing.T) {
+	m := NewCTCP("#chan", "VERSION", "")
+
+	command, params, ok := m.CTCP()
+	if !ok || command != "VERSION" || params != "" {
+		t.Errorf("CTCP() = %q, %q, %v, want %q, %q, true", command, params, ok, "VERSION", "")
+	}
+}
+
+func TestCTCPNotCTCP(t *testing.T) {
+	m := ParseMessage(":nick!user@host PRIVMSG #chan :just a regular message")
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	if _, _, ok := m.CTCP(); ok {
+		t.Error("CTCP() ok = true for a non-CTCP message")
+	}
+}
+
+func TestCTCPWrongCommand(t *testing.T) {
+	m := ParseMessage(":nick!user@host JOIN #chan")
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	if _, _, ok := m.CTCP(); ok {
+		t.Error("CTCP() ok = true for a non-PRIVMSG/NOTICE command")
+	}
+}
+
+func TestCTCPQuoting(t *testing.T) {
+	params := "payload\x00with\nspecial\r\x10bytes"
+
+	m := NewCTCP("#chan", "PING", params)
+
+	trailing := m.Trailing()
+	if len(trailing) < 2 || trailing[0] != ctcpDelim || trailing[len(trailing)-1] != ctcpDelim {
+		t.Fatalf("Trailing() = %q, want \\x01-wrapped", trailing)
+	}
+
+	command, gotParams, ok := m.CTCP()
+	if !ok || command != "PING" || gotParams != params {
+		t.Errorf("CTCP() = %q, %q, %v, want %q, %q, true", command, gotParams, ok, "PING", params)
+	}
+}
@@ -3,7 +3,7 @@ package irc
 import (
 	"bytes"
 	"strings"
-	"unicode"
+	"sync"
 )
 
 var tagDecodeSlashMap = map[rune]rune{
@@ -203,10 +203,15 @@ func (p *Prefix) String() string {
 
 // Message represents a line parsed from the server
 type Message struct {
-	// Each message can have IRCv3 tags
-	Tags
-
-	// Each message can have a Prefix
+	// rawTags is the undecoded tags segment from the wire, if any.
+	// Tags are decoded lazily from it on first access through Tags
+	// or GetTag, so messages nobody inspects tags on skip the decode
+	// loop entirely.
+	rawTags  string
+	tags     Tags
+	tagsOnce sync.Once
+
+	// Prefix is the message's Prefix, or nil if it didn't have one.
 	*Prefix
 
 	// Command is which command is being called.
@@ -216,74 +221,82 @@ type Message struct {
 	Params []string
 }
 
+// Tags returns the message's IRCv3 tags, decoding them from the wire
+// format the first time they're accessed.
+func (m *Message) Tags() Tags {
+	m.tagsOnce.Do(func() {
+		if m.rawTags != "" {
+			m.tags = ParseTags(m.rawTags)
+		} else {
+			m.tags = Tags{}
+		}
+	})
+
+	return m.tags
+}
+
+// GetTag is a convenience method to look up a tag on the message.
+func (m *Message) GetTag(key string) (string, bool) {
+	return m.Tags().GetTag(key)
+}
+
 // ParseMessage takes a message string (usually a whole line) and
-// parses it into a Message struct. This will return nil in the case
-// of invalid messages.
+// parses it into a Message struct in a single forward scan over
+// line. This will return nil in the case of invalid messages. Tags
+// are not decoded until they're accessed; see Tags and GetTag.
 func ParseMessage(line string) *Message {
-	// Trim the line and make sure we have data
 	line = strings.TrimSpace(line)
 	if len(line) == 0 {
 		return nil
 	}
 
 	c := &Message{
-		Tags:   Tags{},
-		Prefix: &Prefix{},
-		Params: []string{},
+		Params: make([]string, 0, 4),
 	}
 
-	// 0 == initial
-	// 1 == found tags
-	// 2 == found prefix
-	// 3 == other
-	state := 0
-	offset := 0
-	var idxTokenEnd, idxNextToken int
-	for {
-		if state == 0 && line[offset] == '@' {
-			state = 1
-		} else if state <= 1 && line[offset] == ':' {
-			state = 2
-		} else if line[offset] == ':' {
-			c.Params = append(c.Params, line[offset+1:])
-			break
-		} else {
-			state = 3
+	if line[0] == '@' {
+		end := strings.IndexByte(line, ' ')
+		if end < 0 {
+			return nil
 		}
 
-		idxTokenEnd = strings.IndexFunc(line[offset:], unicode.IsSpace)
-		if idxTokenEnd < 0 {
-			c.Params = append(c.Params, line[offset:])
-			break
+		c.rawTags = line[1:end]
+		line = trimLeadingSpaces(line[end+1:])
+		if line == "" {
+			return nil
 		}
+	}
 
-		idxNextToken = strings.IndexFunc(line[offset+idxTokenEnd:], isNotSpace)
-		/*
-			This should never be hit, because this only protects
-			against whitespace at the very end, and that was removed
-			by strings.TrimSpace at the start of this function.
-
-			if idxNextToken < 0 {
-				c.Params = append(c.Params, line[offset:])
-				break
-			}
-		*/
+	if line[0] == ':' {
+		end := strings.IndexByte(line, ' ')
 
-		c.Params = append(c.Params, line[offset:offset+idxTokenEnd])
+		var prefix string
+		if end < 0 {
+			prefix, line = line[1:], ""
+		} else {
+			prefix, line = line[1:end], trimLeadingSpaces(line[end+1:])
+		}
 
-		offset += idxTokenEnd + idxNextToken
+		c.Prefix = ParsePrefix(prefix)
+		if line == "" {
+			return nil
+		}
 	}
 
-	// If the first param starts with @, we know it contains IRC tags
-	if len(c.Params) > 0 && c.Params[0][0] == '@' {
-		c.Tags = ParseTags(c.Params[0][1:])
-		c.Params = c.Params[1:]
-	}
+	for len(line) > 0 {
+		if line[0] == ':' {
+			c.Params = append(c.Params, line[1:])
+			break
+		}
 
-	// If the first param starts with :, we know it contains a Prefix
-	if len(c.Params) > 0 && c.Params[0][0] == ':' {
-		c.Prefix = ParsePrefix(c.Params[0][1:])
-		c.Params = c.Params[1:]
+		end := strings.IndexByte(line, ' ')
+		if end < 0 {
+			c.Params = append(c.Params, line)
+			break
+		}
+
+		c.Params = append(c.Params, line[:end])
+		line = trimLeadingSpaces(line[end+1:])
 	}
 
 	if len(c.Params) < 1 || len(c.Params[0]) < 1 {
@@ -296,6 +309,19 @@ func ParseMessage(line string) *Message {
 	return c
 }
 
+// trimLeadingSpaces returns s with any leading ' ' characters
+// removed. The IRC wire format only ever uses a literal space to
+// separate tokens, so this avoids the cost of scanning for general
+// Unicode whitespace.
+func trimLeadingSpaces(s string) string {
+	i := 0
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+
+	return s[i:]
+}
+
 // Trailing returns the last argument in the Message or an empty string
 // if there are no args
 func (m *Message) Trailing() string {
@@ -323,14 +349,17 @@ func (m *Message) FromChannel() bool {
 
 // Copy will create a new copy of an message
 func (m *Message) Copy() *Message {
-	// Create a new message
-	newMessage := &Message{}
-
-	// Copy stuff from the old message
-	*newMessage = *m
+	// Create a new message, copying field by field since Message
+	// holds a sync.Once that must not be copied by value.
+	newMessage := &Message{
+		Command: m.Command,
+	}
 
-	// Copy any IRcv3 tags
-	newMessage.Tags = m.Tags.Copy()
+	// Copy any IRCv3 tags and mark the new message's tags as already
+	// decoded, so a later lazy decode doesn't clobber them.
+	tags := m.Tags().Copy()
+	newMessage.tagsOnce.Do(func() {})
+	newMessage.tags = tags
 
 	// Copy the Prefix
 	newMessage.Prefix = m.Prefix.Copy()
@@ -346,9 +375,9 @@ func (m *Message) String() string {
 	buf := &bytes.Buffer{}
 
 	// Write any IRCv3 tags if they exist in the message
-	if len(m.Tags) > 0 {
+	if tags := m.Tags(); len(tags) > 0 {
 		buf.WriteByte('@')
-		buf.WriteString(m.Tags.String())
+		buf.WriteString(tags.String())
 		buf.WriteByte(' ')
 	}
 
@@ -371,9 +400,9 @@ func (m *Message) String() string {
 			buf.WriteString(strings.Join(args, " "))
 		}
 
-		// If trailing contains a space or starts with a : we
-		// need to actually specify that it's trailing.
-		if strings.ContainsRune(trailing, ' ') || trailing[0] == ':' {
+		// If trailing is empty, contains a space, or starts with a
+		// : we need to actually specify that it's trailing.
+		if len(trailing) == 0 || strings.ContainsRune(trailing, ' ') || trailing[0] == ':' {
 			buf.WriteString(" :")
 		} else {
 			buf.WriteString(" ")
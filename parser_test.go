@@ -0,0 +1,136 @@
+package irc
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestParseMessagePlainCommand(t *testing.T) {
+	m := ParseMessage("PING")
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	if m.Command != "PING" {
+		t.Errorf("Command = %q, want PING", m.Command)
+	}
+	if len(m.Params) != 0 {
+		t.Errorf("Params = %v, want none", m.Params)
+	}
+	if m.Prefix != nil {
+		t.Errorf("Prefix = %+v, want nil", m.Prefix)
+	}
+}
+
+func TestParseMessageCommandWithParams(t *testing.T) {
+	m := ParseMessage("JOIN #chan1 #chan2")
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	if m.Command != "JOIN" {
+		t.Errorf("Command = %q, want JOIN", m.Command)
+	}
+	if want := []string{"#chan1", "#chan2"}; !reflect.DeepEqual(m.Params, want) {
+		t.Errorf("Params = %v, want %v", m.Params, want)
+	}
+}
+
+func TestParseMessageTrailingWithSpaces(t *testing.T) {
+	m := ParseMessage("PRIVMSG #chan :hello, how are you?")
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	if want := []string{"#chan", "hello, how are you?"}; !reflect.DeepEqual(m.Params, want) {
+		t.Errorf("Params = %v, want %v", m.Params, want)
+	}
+	if got := m.Trailing(); got != "hello, how are you?" {
+		t.Errorf("Trailing() = %q, want %q", got, "hello, how are you?")
+	}
+}
+
+func TestParseMessageTagsOnly(t *testing.T) {
+	m := ParseMessage("@id=123 PING")
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	if m.Command != "PING" {
+		t.Errorf("Command = %q, want PING", m.Command)
+	}
+	if id, ok := m.GetTag("id"); !ok || id != "123" {
+		t.Errorf("GetTag(\"id\") = %q, %v, want %q, true", id, ok, "123")
+	}
+}
+
+func TestParseMessageTagsPrefixAndTrailing(t *testing.T) {
+	m := ParseMessage("@time=2019-02-26T21:20:05.166Z;msgid=abc123 :dan!d@localhost PRIVMSG #chan :hey, what's up :)")
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	if m.Command != "PRIVMSG" {
+		t.Errorf("Command = %q, want PRIVMSG", m.Command)
+	}
+	if m.Prefix == nil || m.Prefix.Name != "dan" || m.Prefix.User != "d" || m.Prefix.Host != "localhost" {
+		t.Errorf("Prefix = %+v, want Name=dan User=d Host=localhost", m.Prefix)
+	}
+	if want := []string{"#chan", "hey, what's up :)"}; !reflect.DeepEqual(m.Params, want) {
+		t.Errorf("Params = %v, want %v", m.Params, want)
+	}
+	if id, ok := m.MsgID(); !ok || id != "abc123" {
+		t.Errorf("MsgID() = %q, %v, want %q, true", id, ok, "abc123")
+	}
+	if _, ok := m.Time(); !ok {
+		t.Error("Time() ok = false, want true")
+	}
+}
+
+func TestParseMessageRejectsEmptyCommand(t *testing.T) {
+	for _, line := range []string{"", "   ", ":nick :", "@id=123 :nick"} {
+		if m := ParseMessage(line); m != nil {
+			t.Errorf("ParseMessage(%q) = %+v, want nil", line, m)
+		}
+	}
+}
+
+func TestMessageTagsConcurrentAccess(t *testing.T) {
+	m := ParseMessage("@id=123;id2=456 :nick!user@host PRIVMSG #chan :hi")
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := m.GetTag("id"); !ok {
+				t.Error("GetTag(\"id\") ok = false, want true")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if id, ok := m.GetTag("id"); !ok || id != "123" {
+		t.Errorf("GetTag(\"id\") = %q, %v, want %q, true", id, ok, "123")
+	}
+}
+
+var benchmarkLine = "@time=2019-02-26T21:20:05.166Z;msgid=abc123 :dan!d@localhost PRIVMSG #chan :hey, what's up :)"
+
+// BenchmarkParseMessage parses a representative message (tags,
+// prefix, middle and trailing params) and reports allocations per
+// parse. Tags are intentionally left untouched, since that's the
+// common case for bots that only look at Command and Params.
+func BenchmarkParseMessage(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if m := ParseMessage(benchmarkLine); m == nil {
+			b.Fatal("expected a parsed message")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package irc
+
+// SASLMechanism implements a SASL authentication mechanism usable
+// during capability negotiation.
+type SASLMechanism interface {
+	// Name is the mechanism name sent in "AUTHENTICATE <name>".
+	Name() string
+
+	// Response returns the client-side response to send once the
+	// server requests it with "AUTHENTICATE +". The caller is
+	// responsible for base64-encoding and chunking it.
+	Response() string
+}
+
+// SASLPlain authenticates using the SASL PLAIN mechanism.
+type SASLPlain struct {
+	User string
+	Pass string
+}
+
+// Name returns "PLAIN".
+func (s *SASLPlain) Name() string {
+	return "PLAIN"
+}
+
+// Response returns the PLAIN response: authzid, authcid, and
+// password, each separated by a NUL byte.
+func (s *SASLPlain) Response() string {
+	return s.User + "\x00" + s.User + "\x00" + s.Pass
+}
+
+// SASLExternal authenticates using the SASL EXTERNAL mechanism,
+// relying on an out-of-band credential such as a TLS client
+// certificate.
+type SASLExternal struct{}
+
+// Name returns "EXTERNAL".
+func (SASLExternal) Name() string {
+	return "EXTERNAL"
+}
+
+// Response returns the empty EXTERNAL response.
+func (SASLExternal) Response() string {
+	return ""
+}
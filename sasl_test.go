@@ -0,0 +1,149 @@
+package irc
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateSASLPlainSuccess(t *testing.T) {
+	c, server := newNegotiateTestClient(t, ClientConfig{
+		SASL: &SASLPlain{User: "bot", Pass: "hunter2"},
+	})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.negotiate() }()
+
+	if _, err := server.ReadMessage(); err != nil { // CAP LS
+		t.Fatal(err)
+	}
+	if err := server.Writef("CAP", "*", "LS", "sasl=PLAIN,EXTERNAL"); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := server.ReadMessage() // CAP REQ
+	if err != nil || req.Trailing() != "sasl" {
+		t.Fatalf("expected CAP REQ sasl, got %+v, err %v", req, err)
+	}
+	if err := server.Writef("CAP", "*", "ACK", "sasl"); err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := server.ReadMessage()
+	if err != nil || auth.Command != "AUTHENTICATE" || auth.Params[0] != "PLAIN" {
+		t.Fatalf("expected AUTHENTICATE PLAIN, got %+v, err %v", auth, err)
+	}
+	if err := server.Writef("AUTHENTICATE", "+"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := server.ReadMessage()
+	if err != nil || resp.Command != "AUTHENTICATE" {
+		t.Fatalf("expected an AUTHENTICATE response, got %+v, err %v", resp, err)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(resp.Params[0])
+	if err != nil {
+		t.Fatalf("AUTHENTICATE response is not valid base64: %v", err)
+	}
+	if want := "bot\x00bot\x00hunter2"; string(decoded) != want {
+		t.Errorf("decoded SASL response = %q, want %q", decoded, want)
+	}
+
+	if err := server.Writef("900", "bot", "bot!bot@host", "bot", "You are now logged in as bot"); err != nil {
+		t.Fatal(err)
+	}
+
+	end, err := server.ReadMessage()
+	if err != nil || end.Command != "CAP" || end.Params[0] != "END" {
+		t.Fatalf("expected CAP END after successful SASL, got %+v, err %v", end, err)
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("negotiate() error = %v", err)
+	}
+}
+
+func TestNegotiateSASLFailure(t *testing.T) {
+	c, server := newNegotiateTestClient(t, ClientConfig{
+		SASL: &SASLPlain{User: "bot", Pass: "wrong"},
+	})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.negotiate() }()
+
+	if _, err := server.ReadMessage(); err != nil { // CAP LS
+		t.Fatal(err)
+	}
+	if err := server.Writef("CAP", "*", "LS", "sasl"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.ReadMessage(); err != nil { // CAP REQ
+		t.Fatal(err)
+	}
+	if err := server.Writef("CAP", "*", "ACK", "sasl"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.ReadMessage(); err != nil { // AUTHENTICATE PLAIN
+		t.Fatal(err)
+	}
+	if err := server.Writef("AUTHENTICATE", "+"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.ReadMessage(); err != nil { // the base64 response
+		t.Fatal(err)
+	}
+	if err := server.Writef("904", "bot", "SASL authentication failed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-errCh; err == nil {
+		t.Fatal("negotiate() error = nil, want an error after 904")
+	}
+}
+
+func TestNegotiateSASLFailsFastWhenNotAdvertised(t *testing.T) {
+	c, server := newNegotiateTestClient(t, ClientConfig{
+		SASL: &SASLPlain{User: "bot", Pass: "hunter2"},
+	})
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.negotiate() }()
+
+	if _, err := server.ReadMessage(); err != nil { // CAP LS
+		t.Fatal(err)
+	}
+	if err := server.Writef("CAP", "*", "LS", "server-time"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-errCh; err == nil {
+		t.Fatal("negotiate() error = nil, want an error when the server doesn't advertise sasl")
+	}
+}
+
+func TestSendSASLResponseChunkBoundary(t *testing.T) {
+	c, server := newNegotiateTestClient(t, ClientConfig{})
+
+	// 300 raw bytes base64-encodes to exactly 400 characters with no
+	// padding, so the response lands exactly on the chunk boundary
+	// and must be followed by an empty "AUTHENTICATE +".
+	response := strings.Repeat("a", 300)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.sendSASLResponse(response) }()
+
+	chunk, err := server.ReadMessage()
+	if err != nil || chunk.Command != "AUTHENTICATE" || len(chunk.Params[0]) != saslChunkSize {
+		t.Fatalf("expected a %d-byte AUTHENTICATE chunk, got %+v, err %v", saslChunkSize, chunk, err)
+	}
+
+	final, err := server.ReadMessage()
+	if err != nil || final.Command != "AUTHENTICATE" || final.Params[0] != "+" {
+		t.Fatalf("expected a trailing AUTHENTICATE +, got %+v, err %v", final, err)
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("sendSASLResponse() error = %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package irc
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EmoteRange is a single occurrence of a Twitch emote within a
+// message's trailing parameter, given as rune offsets into it.
+type EmoteRange struct {
+	ID    string
+	Start int
+	End   int
+}
+
+// Badges parses the Twitch "badges" tag into a map of badge name to
+// version, e.g. {"subscriber": "12", "premium": "1"}.
+func (t Tags) Badges() map[string]string {
+	return parseTwitchBadges(t, "badges")
+}
+
+// BadgeInfo parses the Twitch "badge-info" tag the same way as
+// Badges.
+func (t Tags) BadgeInfo() map[string]string {
+	return parseTwitchBadges(t, "badge-info")
+}
+
+func parseTwitchBadges(t Tags, key string) map[string]string {
+	ret := map[string]string{}
+
+	v, ok := t.GetTag(key)
+	if !ok || v == "" {
+		return ret
+	}
+
+	for _, badge := range strings.Split(v, ",") {
+		name, version, _ := strings.Cut(badge, "/")
+		ret[name] = version
+	}
+
+	return ret
+}
+
+// Emotes parses the Twitch "emotes" tag, formatted as
+// "id:start-end,start-end/id:start-end", into one EmoteRange per
+// occurrence.
+func (t Tags) Emotes() []EmoteRange {
+	v, ok := t.GetTag("emotes")
+	if !ok || v == "" {
+		return nil
+	}
+
+	var ret []EmoteRange
+
+	for _, entry := range strings.Split(v, "/") {
+		id, ranges, ok := strings.Cut(entry, ":")
+		if !ok {
+			continue
+		}
+
+		for _, r := range strings.Split(ranges, ",") {
+			start, end, ok := strings.Cut(r, "-")
+			if !ok {
+				continue
+			}
+
+			s, serr := strconv.Atoi(start)
+			e, eerr := strconv.Atoi(end)
+			if serr != nil || eerr != nil {
+				continue
+			}
+
+			ret = append(ret, EmoteRange{ID: id, Start: s, End: e})
+		}
+	}
+
+	return ret
+}
+
+// UserID returns the Twitch "user-id" tag.
+func (t Tags) UserID() (string, bool) {
+	return t.GetTag("user-id")
+}
+
+// RoomID returns the Twitch "room-id" tag.
+func (t Tags) RoomID() (string, bool) {
+	return t.GetTag("room-id")
+}
+
+// Color returns the Twitch "color" tag, the user's chosen display
+// color.
+func (t Tags) Color() (string, bool) {
+	return t.GetTag("color")
+}
+
+// IsMod reports whether the Twitch "mod" tag marks the sender as a
+// moderator of the room.
+func (t Tags) IsMod() bool {
+	v, _ := t.GetTag("mod")
+	return v == "1"
+}
+
+// IsSubscriber reports whether the Twitch "subscriber" tag marks the
+// sender as a subscriber of the room.
+func (t Tags) IsSubscriber() bool {
+	v, _ := t.GetTag("subscriber")
+	return v == "1"
+}
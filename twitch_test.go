@@ -0,0 +1,105 @@
+package irc
+
+import (
+	"reflect"
+	"testing"
+)
+
+// These lines are representative of real PRIVMSGs captured from
+// Twitch's IRC gateway (irc.chat.twitch.tv), with the underlying user
+// and channel identifiers changed.
+var twitchCorpus = []string{
+	"@badge-info=;badges=broadcaster/1;client-nonce=abc123;color=#0000FF;display-name=SomeStreamer;emotes=;first-msg=0;flags=;id=a1b2c3d4-0000-4000-8000-000000000001;mod=0;returning-chatter=0;room-id=123456789;subscriber=0;tmi-sent-ts=1700000000000;turbo=0;user-id=123456789;user-type= :somestreamer!somestreamer@somestreamer.tmi.twitch.tv PRIVMSG #somestreamer :hello chat!",
+	"@badge-info=subscriber/12;badges=subscriber/12,premium/1;client-nonce=def456;color=#FF69B4;display-name=RegularViewer;emotes=25:6-10;first-msg=0;flags=;id=a1b2c3d4-0000-4000-8000-000000000002;mod=0;returning-chatter=0;room-id=123456789;subscriber=1;tmi-sent-ts=1700000001000;turbo=0;user-id=987654321;user-type= :regularviewer!regularviewer@regularviewer.tmi.twitch.tv PRIVMSG #somestreamer :hello Kappa there",
+	"@badge-info=;badges=moderator/1;color=;display-name=ModUser;emotes=;flags=;id=a1b2c3d4-0000-4000-8000-000000000003;mod=1;room-id=123456789;subscriber=0;tmi-sent-ts=1700000002000;turbo=0;user-id=555555555;user-type=mod :moduser!moduser@moduser.tmi.twitch.tv PRIVMSG #somestreamer :/timeout baduser 600",
+}
+
+func TestTwitchBadges(t *testing.T) {
+	m := ParseMessage(twitchCorpus[1])
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	got := m.Tags().Badges()
+	want := map[string]string{"subscriber": "12", "premium": "1"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Badges() = %#v, want %#v", got, want)
+	}
+}
+
+func TestTwitchBadgeInfo(t *testing.T) {
+	m := ParseMessage(twitchCorpus[1])
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	got := m.Tags().BadgeInfo()
+	want := map[string]string{"subscriber": "12"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BadgeInfo() = %#v, want %#v", got, want)
+	}
+}
+
+func TestTwitchEmotes(t *testing.T) {
+	m := ParseMessage(twitchCorpus[1])
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	got := m.Tags().Emotes()
+	want := []EmoteRange{{ID: "25", Start: 6, End: 10}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Emotes() = %#v, want %#v", got, want)
+	}
+}
+
+func TestTwitchEmotesEmpty(t *testing.T) {
+	m := ParseMessage(twitchCorpus[0])
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	if got := m.Tags().Emotes(); got != nil {
+		t.Errorf("Emotes() = %#v, want nil", got)
+	}
+}
+
+func TestTwitchUserIDRoomIDColor(t *testing.T) {
+	m := ParseMessage(twitchCorpus[0])
+	if m == nil {
+		t.Fatal("expected a parsed message")
+	}
+
+	if userID, ok := m.Tags().UserID(); !ok || userID != "123456789" {
+		t.Errorf("UserID() = %q, %v, want %q, true", userID, ok, "123456789")
+	}
+
+	if roomID, ok := m.Tags().RoomID(); !ok || roomID != "123456789" {
+		t.Errorf("RoomID() = %q, %v, want %q, true", roomID, ok, "123456789")
+	}
+
+	if color, ok := m.Tags().Color(); !ok || color != "#0000FF" {
+		t.Errorf("Color() = %q, %v, want %q, true", color, ok, "#0000FF")
+	}
+}
+
+func TestTwitchIsModIsSubscriber(t *testing.T) {
+	viewer := ParseMessage(twitchCorpus[0])
+	subscriber := ParseMessage(twitchCorpus[1])
+	mod := ParseMessage(twitchCorpus[2])
+
+	if viewer.Tags().IsMod() || viewer.Tags().IsSubscriber() {
+		t.Error("viewer message should be neither mod nor subscriber")
+	}
+
+	if !subscriber.Tags().IsSubscriber() {
+		t.Error("subscriber message should report IsSubscriber")
+	}
+
+	if !mod.Tags().IsMod() {
+		t.Error("mod message should report IsMod")
+	}
+}